@@ -0,0 +1,56 @@
+package agentendpoint
+
+import (
+	"context"
+	"math/rand"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Decorrelated-jitter backoff parameters, exposed as vars so tests can
+// override them rather than waiting out real delays.
+var (
+	backoffBase = time.Second
+	backoffCap  = 30 * time.Second
+)
+
+// nextBackoff computes the next decorrelated-jitter delay given the previous
+// one, per https://www.awsarchitectureblog.com/2015/03/backoff.html.
+func nextBackoff(prev time.Duration) time.Duration {
+	if prev < backoffBase {
+		prev = backoffBase
+	}
+	d := backoffBase + time.Duration(rand.Int63n(int64(prev*3-backoffBase+1)))
+	if d > backoffCap {
+		d = backoffCap
+	}
+	return d
+}
+
+// sleepBackoff advances backoff to its next value and sleeps that long,
+// returning false if ctx is cancelled first.
+func sleepBackoff(ctx context.Context, backoff *time.Duration) bool {
+	*backoff = nextBackoff(*backoff)
+
+	t := time.NewTimer(*backoff)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-t.C:
+		return true
+	}
+}
+
+// isTransientErr reports whether err is a gRPC error worth retrying, as
+// opposed to a permanent failure that should abort the retry loop.
+func isTransientErr(err error) bool {
+	switch status.Code(err) {
+	case codes.Unavailable, codes.DeadlineExceeded:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,33 @@
+package agentendpoint
+
+import (
+	"context"
+
+	agentendpointpb "github.com/GoogleCloudPlatform/osconfig/internal/google.golang.org/genproto/googleapis/cloud/osconfig/agentendpoint/v1alpha1"
+)
+
+// Client reports inventory (and, elsewhere, task state) to the agent
+// endpoint over the wrapped gRPC service client.
+type Client struct {
+	client agentendpointpb.AgentEndpointServiceClient
+}
+
+// NewClient returns a Client that reports through the given service client.
+func NewClient(client agentendpointpb.AgentEndpointServiceClient) *Client {
+	return &Client{client: client}
+}
+
+func (c *Client) reportInventory(ctx context.Context, inv *agentendpointpb.Inventory, reportFullInventory bool) (*agentendpointpb.ReportInventoryResponse, error) {
+	return c.client.ReportInventory(ctx, &agentendpointpb.ReportInventoryRequest{
+		Inventory:           inv,
+		ReportFullInventory: reportFullInventory,
+	})
+}
+
+func (c *Client) reportInventoryDelta(ctx context.Context, osInfo *agentendpointpb.Inventory_OsInfo, installed, available *agentendpointpb.Inventory_PackageDelta) (*agentendpointpb.ReportInventoryResponse, error) {
+	return c.client.ReportInventoryDelta(ctx, &agentendpointpb.ReportInventoryDeltaRequest{
+		OsInfo:            osInfo,
+		InstalledPackages: installed,
+		AvailablePackages: available,
+	})
+}
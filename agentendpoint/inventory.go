@@ -2,9 +2,12 @@ package agentendpoint
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"os"
 	"reflect"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/GoogleCloudPlatform/guest-logging-go/logger"
@@ -13,67 +16,238 @@ import (
 	agentendpointpb "github.com/GoogleCloudPlatform/osconfig/internal/google.golang.org/genproto/googleapis/cloud/osconfig/agentendpoint/v1alpha1"
 	"github.com/GoogleCloudPlatform/osconfig/inventory"
 	"github.com/GoogleCloudPlatform/osconfig/packages"
+	"github.com/hashicorp/go-multierror"
 	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
 const (
 	inventoryURL = config.ReportURL + "/guestInventory"
 	maxRetries   = 5
+
+	// defaultWriteWorkers is how many guest-attribute writes run concurrently
+	// when config doesn't override it.
+	defaultWriteWorkers = 4
+)
+
+// postAttribute and postAttributeCompressed are indirected through vars so
+// tests can substitute fakes.
+var (
+	postAttribute           = attributes.PostAttribute
+	postAttributeCompressed = attributes.PostAttributeCompressed
 )
 
 // ReportInventory reports inventory to agent endpoint and writes it to guest attributes.
 func (c *Client) ReportInventory(ctx context.Context) {
 	state := inventory.Get()
-	write(state, inventoryURL)
+	if err := write(state, inventoryURL); err != nil {
+		logger.Errorf("Error writing inventory to guest attributes: %v", err)
+	}
 	c.report(ctx, state)
 }
 
-func write(state *inventory.InstanceInventory, url string) {
+// writeJob is one field's worth of guest-attribute post, queued for a worker.
+type writeJob struct {
+	url       string
+	name      string
+	hash      string
+	isStruct  bool
+	strVal    string
+	structVal interface{}
+}
+
+// write posts each field of state to its own guest-attribute URL, using a
+// bounded worker pool since fields are independent and the URLs don't
+// collide. It returns an aggregate of any per-field errors.
+func write(state *inventory.InstanceInventory, url string) error {
 	logger.Debugf("Writing instance inventory to guest attributes.")
 
+	cache, err := loadInventoryHashCache()
+	if err != nil {
+		if !os.IsNotExist(err) {
+			logger.Errorf("Error loading inventory hash cache, starting from empty: %v", err)
+		}
+		cache = newInventoryHashCache()
+	}
+	if config.ForceFullInventory() {
+		cache = newInventoryHashCache()
+	}
+
+	workers := config.InventoryWriteWorkers()
+	if workers < 1 {
+		workers = defaultWriteWorkers
+	}
+
+	jobs := make(chan writeJob)
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	var errs *multierror.Error
+
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for job := range jobs {
+				var err error
+				if job.isStruct {
+					logger.Debugf("postAttributeCompressed %s: %+v", job.url, job.structVal)
+					err = postAttributeCompressed(job.url, job.structVal)
+				} else {
+					logger.Debugf("postAttribute %s: %+v", job.url, job.strVal)
+					err = postAttribute(job.url, strings.NewReader(job.strVal))
+				}
+				if err != nil {
+					mu.Lock()
+					errs = multierror.Append(errs, fmt.Errorf("%s: %w", job.url, err))
+					mu.Unlock()
+					continue
+				}
+				cache.commitField(job.name, job.hash)
+			}
+		}()
+	}
+
 	e := reflect.ValueOf(state).Elem()
 	t := e.Type()
 	for i := 0; i < e.NumField(); i++ {
 		f := e.Field(i)
-		u := fmt.Sprintf("%s/%s", url, t.Field(i).Name)
+		name := t.Field(i).Name
+		u := fmt.Sprintf("%s/%s", url, name)
 		switch f.Kind() {
 		case reflect.String:
-			logger.Debugf("postAttribute %s: %+v", u, f)
-			if err := attributes.PostAttribute(u, strings.NewReader(f.String())); err != nil {
-				logger.Errorf("postAttribute error: %v", err)
+			hash, unchanged := cache.fieldUnchanged(name, []byte(f.String()))
+			if unchanged {
+				continue
 			}
+			jobs <- writeJob{url: u, name: name, hash: hash, strVal: f.String()}
 		case reflect.Struct:
-			logger.Debugf("postAttributeCompressed %s: %+v", u, f)
-			if err := attributes.PostAttributeCompressed(u, f.Interface()); err != nil {
-				logger.Errorf("postAttributeCompressed error: %v", err)
+			data, err := json.Marshal(f.Interface())
+			if err != nil {
+				mu.Lock()
+				errs = multierror.Append(errs, fmt.Errorf("%s: marshaling for hash: %w", u, err))
+				mu.Unlock()
+				continue
+			}
+			hash, unchanged := cache.fieldUnchanged(name, data)
+			if unchanged {
+				continue
 			}
+			jobs <- writeJob{url: u, name: name, hash: hash, isStruct: true, structVal: f.Interface()}
 		}
 	}
+	close(jobs)
+	wg.Wait()
+
+	if err := cache.save(); err != nil {
+		logger.Errorf("Error saving inventory hash cache: %v", err)
+	}
+
+	return errs.ErrorOrNil()
 }
 
 func (c *Client) report(ctx context.Context, state *inventory.InstanceInventory) {
 	logger.Debugf("Reporting instance inventory to agent endpoint.")
-	inventory := formatInventory(state)
+	inv := formatInventory(state)
 
-	reportFull := false
-	retries := 0
+	cache, err := loadInventoryHashCache()
+	// write() saves the hash file before report() runs, so a file that holds
+	// no package hashes yet counts as missing too.
+	missingCache := os.IsNotExist(err) || (err == nil && len(cache.Packages) == 0 && len(cache.AvailablePackages) == 0)
+	corruptCache := err != nil && !os.IsNotExist(err)
+	if corruptCache {
+		logger.Errorf("Error loading inventory hash cache, falling back to full inventory report: %v", err)
+	}
+	if missingCache || corruptCache {
+		cache = newInventoryHashCache()
+	}
+
+	// A missing cache (first boot) gets the same full-report fallback as a
+	// corrupt one: with no recorded hashes, a delta report would describe
+	// every installed and available package as newly "added".
+	reportFull := config.ForceFullInventory() || missingCache || corruptCache
+	backoff := backoffBase
+	errorRetries, fullInventoryRetries := 0, 0
+
+	// Computed once, outside the loop: a retry must keep diffing against the
+	// same snapshot, not whatever the cache looked like after an earlier
+	// attempt in this same report() call.
+	prevInstalled := cache.snapshotPackages()
+	nextInstalled := packageHashes(inv.GetInstalledPackages())
+	prevAvailable := cache.snapshotAvailablePackages()
+	nextAvailable := packageHashes(inv.GetAvailablePackages())
+
+	reported := false
 	for {
-		res, err := c.reportInventory(ctx, inventory, reportFull)
-		if err != nil {
-			logger.Errorf("Error reporting inventory: %v", err)
+		if ctx.Err() != nil {
+			logger.Debugf("Context cancelled, aborting inventory report: %v", ctx.Err())
+			break
+		}
+
+		var res *agentendpointpb.ReportInventoryResponse
+		var rerr error
+		if reportFull {
+			res, rerr = c.reportInventory(ctx, inv, true)
+		} else {
+			installedAdded, installedChanged, installedRemoved := diffPackageHashes(prevInstalled, nextInstalled, inv.GetInstalledPackages())
+			availableAdded, availableChanged, availableRemoved := diffPackageHashes(prevAvailable, nextAvailable, inv.GetAvailablePackages())
+			res, rerr = c.reportInventoryDelta(ctx, inv.GetOsInfo(),
+				&agentendpointpb.Inventory_PackageDelta{
+					AddedPackages:      installedAdded,
+					ChangedPackages:    installedChanged,
+					RemovedPackageKeys: installedRemoved,
+				},
+				&agentendpointpb.Inventory_PackageDelta{
+					AddedPackages:      availableAdded,
+					ChangedPackages:    availableChanged,
+					RemovedPackageKeys: availableRemoved,
+				},
+			)
+		}
+
+		if rerr != nil {
+			logger.Errorf("Error reporting inventory: %v", rerr)
+			if !isTransientErr(rerr) {
+				break
+			}
+
+			errorRetries++
+			if errorRetries >= maxRetries {
+				logger.Errorf("Error reporting inventory: exceeded %d tries", maxRetries)
+				break
+			}
+			if !sleepBackoff(ctx, &backoff) {
+				break
+			}
+			continue
 		}
 
 		if !res.GetReportFullInventory() {
+			reported = true
 			break
-		} else {
-			reportFull = true
 		}
+		reportFull = true
+		backoff = backoffBase
 
-		retries++
-		if retries >= maxRetries {
+		fullInventoryRetries++
+		if fullInventoryRetries >= maxRetries {
 			logger.Errorf("Error reporting inventory: exceeded %d tries", maxRetries)
 			break
 		}
+		if !sleepBackoff(ctx, &backoff) {
+			break
+		}
+	}
+
+	if !reported {
+		return
+	}
+
+	// Only persist the new package hashes once the report they describe is
+	// confirmed delivered; a failed or abandoned attempt must leave the cache
+	// exactly as it was so the next tick re-diffs against reality.
+	cache.commitPackages(nextInstalled)
+	cache.commitAvailablePackages(nextAvailable)
+	if err := cache.save(); err != nil {
+		logger.Errorf("Error saving inventory hash cache: %v", err)
 	}
 }
 
@@ -170,7 +344,48 @@ func formatPackages(packages packages.Packages, shortName string) []*agentendpoi
 			}
 		}
 	}
-	// Ignore Pip and Gem packages.
+	if packages.Apk != nil {
+		for _, pkg := range packages.Apk {
+			softwarePackages = append(softwarePackages, &agentendpointpb.Inventory_SoftwarePackage{
+				Details: formatApkPackage(pkg),
+			})
+		}
+	}
+	if packages.Pacman != nil {
+		for _, pkg := range packages.Pacman {
+			softwarePackages = append(softwarePackages, &agentendpointpb.Inventory_SoftwarePackage{
+				Details: formatPacmanPackage(pkg),
+			})
+		}
+	}
+	if packages.Pip != nil {
+		for _, pkg := range packages.Pip {
+			softwarePackages = append(softwarePackages, &agentendpointpb.Inventory_SoftwarePackage{
+				Details: formatLanguagePackage(pkg, agentendpointpb.Inventory_SoftwarePackage_LanguagePackage_PIP),
+			})
+		}
+	}
+	if packages.Gem != nil {
+		for _, pkg := range packages.Gem {
+			softwarePackages = append(softwarePackages, &agentendpointpb.Inventory_SoftwarePackage{
+				Details: formatLanguagePackage(pkg, agentendpointpb.Inventory_SoftwarePackage_LanguagePackage_GEM),
+			})
+		}
+	}
+	if packages.Npm != nil {
+		for _, pkg := range packages.Npm {
+			softwarePackages = append(softwarePackages, &agentendpointpb.Inventory_SoftwarePackage{
+				Details: formatLanguagePackage(pkg, agentendpointpb.Inventory_SoftwarePackage_LanguagePackage_NPM),
+			})
+		}
+	}
+	if packages.Cargo != nil {
+		for _, pkg := range packages.Cargo {
+			softwarePackages = append(softwarePackages, &agentendpointpb.Inventory_SoftwarePackage{
+				Details: formatLanguagePackage(pkg, agentendpointpb.Inventory_SoftwarePackage_LanguagePackage_CARGO),
+			})
+		}
+	}
 
 	return softwarePackages
 }
@@ -209,6 +424,33 @@ func formatZypperPackage(pkg packages.PkgInfo) *agentendpointpb.Inventory_Softwa
 			Version:      pkg.Version}}
 }
 
+func formatApkPackage(pkg packages.PkgInfo) *agentendpointpb.Inventory_SoftwarePackage_ApkPackage {
+	return &agentendpointpb.Inventory_SoftwarePackage_ApkPackage{
+		ApkPackage: &agentendpointpb.Inventory_VersionedPackage{
+			Name:         pkg.Name,
+			Architecture: pkg.Arch,
+			Version:      pkg.Version,
+		}}
+}
+
+func formatPacmanPackage(pkg packages.PkgInfo) *agentendpointpb.Inventory_SoftwarePackage_PacmanPackage {
+	return &agentendpointpb.Inventory_SoftwarePackage_PacmanPackage{
+		PacmanPackage: &agentendpointpb.Inventory_VersionedPackage{
+			Name:         pkg.Name,
+			Architecture: pkg.Arch,
+			Version:      pkg.Version,
+		}}
+}
+
+func formatLanguagePackage(pkg packages.PkgInfo, ecosystem agentendpointpb.Inventory_SoftwarePackage_LanguagePackage_Ecosystem) *agentendpointpb.Inventory_SoftwarePackage_LanguagePackage {
+	return &agentendpointpb.Inventory_SoftwarePackage_LanguagePackage{
+		LanguagePackage: &agentendpointpb.Inventory_LanguagePackage{
+			Name:      pkg.Name,
+			Version:   pkg.Version,
+			Ecosystem: ecosystem,
+		}}
+}
+
 func formatZypperPatch(pkg packages.ZypperPatch) *agentendpointpb.Inventory_SoftwarePackage_ZypperPatch {
 	return &agentendpointpb.Inventory_SoftwarePackage_ZypperPatch{
 		ZypperPatch: &agentendpointpb.Inventory_ZypperPatch{
@@ -0,0 +1,212 @@
+package agentendpoint
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/GoogleCloudPlatform/osconfig/config"
+	agentendpointpb "github.com/GoogleCloudPlatform/osconfig/internal/google.golang.org/genproto/googleapis/cloud/osconfig/agentendpoint/v1alpha1"
+)
+
+const inventoryHashFileName = "inventory_hashes.json"
+
+func inventoryHashFilePath() string {
+	return filepath.Join(config.StateDir(), inventoryHashFileName)
+}
+
+// inventoryHashCache tracks the SHA-256 hash of the last successfully reported
+// value for each top-level inventory field and each software package, so
+// unchanged data can be skipped on subsequent reporting ticks. Installed and
+// available packages are tracked in separate maps since the same package can
+// legitimately appear in both lists at once.
+type inventoryHashCache struct {
+	mu                sync.Mutex
+	Fields            map[string]string `json:"fields"`
+	Packages          map[string]string `json:"packages"`
+	AvailablePackages map[string]string `json:"available_packages"`
+}
+
+func newInventoryHashCache() *inventoryHashCache {
+	return &inventoryHashCache{
+		Fields:            map[string]string{},
+		Packages:          map[string]string{},
+		AvailablePackages: map[string]string{},
+	}
+}
+
+// loadInventoryHashCache reads the cache from disk. Callers must distinguish
+// a missing file (os.IsNotExist(err), expected on first boot) from any other
+// error (a corrupt or unreadable cache) since the two warrant different
+// fallback behavior.
+func loadInventoryHashCache() (*inventoryHashCache, error) {
+	data, err := os.ReadFile(inventoryHashFilePath())
+	if err != nil {
+		return nil, err
+	}
+
+	c := newInventoryHashCache()
+	if err := json.Unmarshal(data, c); err != nil {
+		return nil, fmt.Errorf("corrupt inventory hash cache: %v", err)
+	}
+	if c.Fields == nil {
+		c.Fields = map[string]string{}
+	}
+	if c.Packages == nil {
+		c.Packages = map[string]string{}
+	}
+	if c.AvailablePackages == nil {
+		c.AvailablePackages = map[string]string{}
+	}
+	return c, nil
+}
+
+func (c *inventoryHashCache) save() error {
+	c.mu.Lock()
+	data, err := json.Marshal(c)
+	c.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(inventoryHashFilePath(), data, 0644)
+}
+
+func hashBytes(b []byte) string {
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:])
+}
+
+// fieldUnchanged reports whether value's hash matches the hash recorded for
+// name from the last *successful* post, without recording anything itself —
+// callers must call commitField once the post for name actually succeeds.
+func (c *inventoryHashCache) fieldUnchanged(name string, value []byte) (hash string, unchanged bool) {
+	h := hashBytes(value)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	prev, ok := c.Fields[name]
+	return h, ok && prev == h
+}
+
+// commitField records hash as the last successfully reported value for name.
+func (c *inventoryHashCache) commitField(name, hash string) {
+	c.mu.Lock()
+	c.Fields[name] = hash
+	c.mu.Unlock()
+}
+
+// snapshotPackages returns a copy of the last successfully reported package
+// hashes, safe to diff against without risk of a concurrent mutation.
+func (c *inventoryHashCache) snapshotPackages() map[string]string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	snap := make(map[string]string, len(c.Packages))
+	for k, v := range c.Packages {
+		snap[k] = v
+	}
+	return snap
+}
+
+// commitPackages replaces the persisted package hashes wholesale. Called only
+// once a report has been confirmed successful.
+func (c *inventoryHashCache) commitPackages(hashes map[string]string) {
+	c.mu.Lock()
+	c.Packages = hashes
+	c.mu.Unlock()
+}
+
+// snapshotAvailablePackages is snapshotPackages for the available-package
+// hashes.
+func (c *inventoryHashCache) snapshotAvailablePackages() map[string]string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	snap := make(map[string]string, len(c.AvailablePackages))
+	for k, v := range c.AvailablePackages {
+		snap[k] = v
+	}
+	return snap
+}
+
+// commitAvailablePackages is commitPackages for the available-package hashes.
+func (c *inventoryHashCache) commitAvailablePackages(hashes map[string]string) {
+	c.mu.Lock()
+	c.AvailablePackages = hashes
+	c.mu.Unlock()
+}
+
+// packageIdentity returns a key identifying pkg's underlying package
+// (independent of version, so a version bump is a "changed" not an
+// "added"+"removed" pair) and a content hash covering the whole message.
+func packageIdentity(pkg *agentendpointpb.Inventory_SoftwarePackage) (key, hash string) {
+	data, err := json.Marshal(pkg)
+	if err != nil {
+		return "", ""
+	}
+
+	switch d := pkg.GetDetails().(type) {
+	case *agentendpointpb.Inventory_SoftwarePackage_AptPackage:
+		key = "apt:" + d.AptPackage.GetName()
+	case *agentendpointpb.Inventory_SoftwarePackage_YumPackage:
+		key = "yum:" + d.YumPackage.GetName()
+	case *agentendpointpb.Inventory_SoftwarePackage_ZypperPackage:
+		key = "zypper:" + d.ZypperPackage.GetName()
+	case *agentendpointpb.Inventory_SoftwarePackage_ZypperPatch:
+		key = "zypper_patch:" + d.ZypperPatch.GetName()
+	case *agentendpointpb.Inventory_SoftwarePackage_GoogetPackage:
+		key = "googet:" + d.GoogetPackage.GetName()
+	case *agentendpointpb.Inventory_SoftwarePackage_WuaPackage:
+		key = "wua:" + d.WuaPackage.GetUpdateId()
+	case *agentendpointpb.Inventory_SoftwarePackage_QfePackage:
+		key = "qfe:" + d.QfePackage.GetHotFixId()
+	case *agentendpointpb.Inventory_SoftwarePackage_ApkPackage:
+		key = "apk:" + d.ApkPackage.GetName()
+	case *agentendpointpb.Inventory_SoftwarePackage_PacmanPackage:
+		key = "pacman:" + d.PacmanPackage.GetName()
+	case *agentendpointpb.Inventory_SoftwarePackage_LanguagePackage:
+		key = fmt.Sprintf("lang:%d:%s", d.LanguagePackage.GetEcosystem(), d.LanguagePackage.GetName())
+	default:
+		key = hex.EncodeToString(data)
+	}
+	return key, hashBytes(data)
+}
+
+// packageHashes computes the content hash of every package in pkgs, keyed by
+// its stable identity. It has no side effects on the cache.
+func packageHashes(pkgs []*agentendpointpb.Inventory_SoftwarePackage) map[string]string {
+	hashes := make(map[string]string, len(pkgs))
+	for _, pkg := range pkgs {
+		key, hash := packageIdentity(pkg)
+		if key == "" {
+			continue
+		}
+		hashes[key] = hash
+	}
+	return hashes
+}
+
+// diffPackageHashes compares prev (the last successfully reported hashes)
+// against next (the current tick's hashes), returning which packages in pkgs
+// are new or changed, and the keys of packages that disappeared.
+func diffPackageHashes(prev, next map[string]string, pkgs []*agentendpointpb.Inventory_SoftwarePackage) (added, changed []*agentendpointpb.Inventory_SoftwarePackage, removed []string) {
+	for _, pkg := range pkgs {
+		key, hash := packageIdentity(pkg)
+		if key == "" {
+			continue
+		}
+		if prevHash, ok := prev[key]; !ok {
+			added = append(added, pkg)
+		} else if prevHash != hash {
+			changed = append(changed, pkg)
+		}
+	}
+
+	for key := range prev {
+		if _, ok := next[key]; !ok {
+			removed = append(removed, key)
+		}
+	}
+	return added, changed, removed
+}
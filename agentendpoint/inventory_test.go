@@ -0,0 +1,93 @@
+package agentendpoint
+
+import (
+	"flag"
+	"io"
+	"sync"
+	"testing"
+
+	"github.com/GoogleCloudPlatform/osconfig/inventory"
+	"github.com/GoogleCloudPlatform/osconfig/packages"
+)
+
+// TestWriteNoURLRace exercises write()'s worker pool with every field
+// populated, so every postAttribute/postAttributeCompressed URL is in play
+// at once. It fails if any URL is posted other than exactly once; run with
+// `go test -race` to also catch unsynchronized access to write()'s shared
+// error accumulator and hash cache.
+func TestWriteNoURLRace(t *testing.T) {
+	origAttr, origCompressed := postAttribute, postAttributeCompressed
+	defer func() { postAttribute, postAttributeCompressed = origAttr, origCompressed }()
+
+	// write() persists its hash cache under config.StateDir(); point that at
+	// a scratch directory so this test never reads or leaves behind real
+	// on-disk state, and every field below is guaranteed "changed".
+	stateDirFlag := flag.Lookup("state-dir")
+	origStateDir := stateDirFlag.Value.String()
+	if err := stateDirFlag.Value.Set(t.TempDir()); err != nil {
+		t.Fatalf("setting state-dir: %v", err)
+	}
+	defer func() { stateDirFlag.Value.Set(origStateDir) }()
+
+	var mu sync.Mutex
+	counts := map[string]int{}
+	record := func(url string) {
+		mu.Lock()
+		counts[url]++
+		mu.Unlock()
+	}
+	postAttribute = func(url string, _ io.Reader) error {
+		record(url)
+		return nil
+	}
+	postAttributeCompressed = func(url string, _ interface{}) error {
+		record(url)
+		return nil
+	}
+
+	state := &inventory.InstanceInventory{
+		Hostname:             "host",
+		LongName:             "Some Linux",
+		ShortName:            "linux",
+		Version:              "1.0",
+		Architecture:         "x86_64",
+		KernelVersion:        "5.0.0",
+		KernelRelease:        "5.0.0-1",
+		OSConfigAgentVersion: "1.2.3",
+		InstalledPackages: packages.Packages{
+			Apt: []packages.PkgInfo{{Name: "foo", Arch: "amd64", Version: "1.0"}},
+		},
+		PackageUpdates: packages.Packages{
+			Yum: []packages.PkgInfo{{Name: "bar", Arch: "x86_64", Version: "2.0"}},
+		},
+	}
+
+	baseURL := "http://metadata.internal/guestInventory"
+	if err := write(state, baseURL); err != nil {
+		t.Fatalf("write() returned error: %v", err)
+	}
+
+	wantURLs := []string{
+		baseURL + "/Hostname",
+		baseURL + "/LongName",
+		baseURL + "/ShortName",
+		baseURL + "/Version",
+		baseURL + "/Architecture",
+		baseURL + "/KernelVersion",
+		baseURL + "/KernelRelease",
+		baseURL + "/OSConfigAgentVersion",
+		baseURL + "/InstalledPackages",
+		baseURL + "/PackageUpdates",
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	for _, url := range wantURLs {
+		if counts[url] != 1 {
+			t.Errorf("url %s was posted %d times, want exactly 1", url, counts[url])
+		}
+	}
+	if len(counts) != len(wantURLs) {
+		t.Errorf("got %d distinct URLs posted, want %d: %v", len(counts), len(wantURLs), counts)
+	}
+}
@@ -0,0 +1,45 @@
+// Package config centralizes osconfig agent flags and runtime configuration
+// so packages don't register their own package-level flags (which causes
+// init-time side effects and flag collisions across tests).
+package config
+
+import (
+	"flag"
+	"path/filepath"
+)
+
+const (
+	// ReportURL is the base metadata URL osconfig reports inventory and task
+	// state under.
+	ReportURL = "http://metadata.google.internal/computeMetadata/v1/instance/guest-attributes"
+
+	defaultStateDir              = "/var/lib/google-guest-agent"
+	defaultInventoryWriteWorkers = 4
+)
+
+var (
+	stateDir = flag.String("state-dir", defaultStateDir, "directory osconfig uses to persist local state between runs")
+
+	forceFullInventory = flag.Bool("force-full-inventory", false, "invalidate the local inventory hash cache and force a full inventory report")
+
+	inventoryWriteWorkers = flag.Int("inventory-write-workers", defaultInventoryWriteWorkers, "number of concurrent guest-attribute writes when reporting inventory")
+)
+
+// StateDir returns the directory osconfig uses to persist local state, such
+// as the inventory hash cache, between runs.
+func StateDir() string {
+	return filepath.Clean(*stateDir)
+}
+
+// ForceFullInventory reports whether --force-full-inventory was set,
+// requesting that the local inventory hash cache be invalidated and a full
+// inventory report sent regardless of what's cached.
+func ForceFullInventory() bool {
+	return *forceFullInventory
+}
+
+// InventoryWriteWorkers returns the configured number of concurrent
+// guest-attribute writes to use when reporting inventory.
+func InventoryWriteWorkers() int {
+	return *inventoryWriteWorkers
+}
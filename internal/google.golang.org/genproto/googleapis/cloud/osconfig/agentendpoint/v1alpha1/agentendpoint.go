@@ -0,0 +1,268 @@
+// Package agentendpoint holds the hand-maintained subset of the generated
+// AgentEndpointService client types that osconfig's business logic depends
+// on. The canonical definitions live in the agentendpoint.proto service and
+// are normally produced by protoc-gen-go; this file tracks the fields that
+// package os-config code actually uses.
+package agentendpoint
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+type Inventory struct {
+	OsInfo            *Inventory_OsInfo
+	InstalledPackages []*Inventory_SoftwarePackage
+	AvailablePackages []*Inventory_SoftwarePackage
+}
+
+func (m *Inventory) GetOsInfo() *Inventory_OsInfo {
+	if m != nil {
+		return m.OsInfo
+	}
+	return nil
+}
+
+func (m *Inventory) GetInstalledPackages() []*Inventory_SoftwarePackage {
+	if m != nil {
+		return m.InstalledPackages
+	}
+	return nil
+}
+
+func (m *Inventory) GetAvailablePackages() []*Inventory_SoftwarePackage {
+	if m != nil {
+		return m.AvailablePackages
+	}
+	return nil
+}
+
+type Inventory_OsInfo struct {
+	HostName             string
+	LongName             string
+	ShortName            string
+	Version              string
+	Architecture         string
+	KernelVersion        string
+	KernelRelease        string
+	OsconfigAgentVersion string
+}
+
+type Inventory_VersionedPackage struct {
+	Name         string
+	Architecture string
+	Version      string
+}
+
+func (m *Inventory_VersionedPackage) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+type Inventory_ZypperPatch struct {
+	Name     string
+	Category string
+	Severity string
+	Summary  string
+}
+
+func (m *Inventory_ZypperPatch) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+type Inventory_WindowsUpdatePackage_WindowsUpdateCategory struct {
+	Id   string
+	Name string
+}
+
+type Inventory_WindowsUpdatePackage struct {
+	Title                    string
+	Description              string
+	Categories               []*Inventory_WindowsUpdatePackage_WindowsUpdateCategory
+	KbArticleIds             []string
+	SupportUrls              []string
+	UpdateId                 string
+	RevisionNumber           int32
+	LastDeploymentChangeTime *timestamppb.Timestamp
+}
+
+func (m *Inventory_WindowsUpdatePackage) GetUpdateId() string {
+	if m != nil {
+		return m.UpdateId
+	}
+	return ""
+}
+
+type Inventory_WindowsQuickFixEngineeringPackage struct {
+	Caption     string
+	Description string
+	HotFixId    string
+	InstalledOn *timestamppb.Timestamp
+}
+
+func (m *Inventory_WindowsQuickFixEngineeringPackage) GetHotFixId() string {
+	if m != nil {
+		return m.HotFixId
+	}
+	return ""
+}
+
+// isInventory_SoftwarePackage_Details is the oneof interface implemented by
+// every "<manager>Package" wrapper below.
+type isInventory_SoftwarePackage_Details interface {
+	isInventory_SoftwarePackage_Details()
+}
+
+type Inventory_SoftwarePackage struct {
+	Details isInventory_SoftwarePackage_Details
+}
+
+func (m *Inventory_SoftwarePackage) GetDetails() isInventory_SoftwarePackage_Details {
+	if m != nil {
+		return m.Details
+	}
+	return nil
+}
+
+type Inventory_SoftwarePackage_AptPackage struct {
+	AptPackage *Inventory_VersionedPackage
+}
+
+func (*Inventory_SoftwarePackage_AptPackage) isInventory_SoftwarePackage_Details() {}
+
+type Inventory_SoftwarePackage_YumPackage struct {
+	YumPackage *Inventory_VersionedPackage
+}
+
+func (*Inventory_SoftwarePackage_YumPackage) isInventory_SoftwarePackage_Details() {}
+
+type Inventory_SoftwarePackage_ZypperPackage struct {
+	ZypperPackage *Inventory_VersionedPackage
+}
+
+func (*Inventory_SoftwarePackage_ZypperPackage) isInventory_SoftwarePackage_Details() {}
+
+type Inventory_SoftwarePackage_ZypperPatch struct {
+	ZypperPatch *Inventory_ZypperPatch
+}
+
+func (*Inventory_SoftwarePackage_ZypperPatch) isInventory_SoftwarePackage_Details() {}
+
+type Inventory_SoftwarePackage_GoogetPackage struct {
+	GoogetPackage *Inventory_VersionedPackage
+}
+
+func (*Inventory_SoftwarePackage_GoogetPackage) isInventory_SoftwarePackage_Details() {}
+
+type Inventory_SoftwarePackage_WuaPackage struct {
+	WuaPackage *Inventory_WindowsUpdatePackage
+}
+
+func (*Inventory_SoftwarePackage_WuaPackage) isInventory_SoftwarePackage_Details() {}
+
+type Inventory_SoftwarePackage_QfePackage struct {
+	QfePackage *Inventory_WindowsQuickFixEngineeringPackage
+}
+
+func (*Inventory_SoftwarePackage_QfePackage) isInventory_SoftwarePackage_Details() {}
+
+// Inventory_SoftwarePackage_ApkPackage and Inventory_SoftwarePackage_PacmanPackage
+// carry Alpine apk and Arch pacman packages respectively, reusing the
+// manager-agnostic VersionedPackage shape.
+type Inventory_SoftwarePackage_ApkPackage struct {
+	ApkPackage *Inventory_VersionedPackage
+}
+
+func (*Inventory_SoftwarePackage_ApkPackage) isInventory_SoftwarePackage_Details() {}
+
+type Inventory_SoftwarePackage_PacmanPackage struct {
+	PacmanPackage *Inventory_VersionedPackage
+}
+
+func (*Inventory_SoftwarePackage_PacmanPackage) isInventory_SoftwarePackage_Details() {}
+
+// Inventory_SoftwarePackage_LanguagePackage_Ecosystem identifies which
+// language-specific package manager a LanguagePackage came from.
+type Inventory_SoftwarePackage_LanguagePackage_Ecosystem int32
+
+const (
+	Inventory_SoftwarePackage_LanguagePackage_ECOSYSTEM_UNSPECIFIED Inventory_SoftwarePackage_LanguagePackage_Ecosystem = 0
+	Inventory_SoftwarePackage_LanguagePackage_PIP                   Inventory_SoftwarePackage_LanguagePackage_Ecosystem = 1
+	Inventory_SoftwarePackage_LanguagePackage_GEM                   Inventory_SoftwarePackage_LanguagePackage_Ecosystem = 2
+	Inventory_SoftwarePackage_LanguagePackage_NPM                   Inventory_SoftwarePackage_LanguagePackage_Ecosystem = 3
+	Inventory_SoftwarePackage_LanguagePackage_CARGO                 Inventory_SoftwarePackage_LanguagePackage_Ecosystem = 4
+)
+
+type Inventory_LanguagePackage struct {
+	Name      string
+	Version   string
+	Ecosystem Inventory_SoftwarePackage_LanguagePackage_Ecosystem
+}
+
+func (m *Inventory_LanguagePackage) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *Inventory_LanguagePackage) GetEcosystem() Inventory_SoftwarePackage_LanguagePackage_Ecosystem {
+	if m != nil {
+		return m.Ecosystem
+	}
+	return Inventory_SoftwarePackage_LanguagePackage_ECOSYSTEM_UNSPECIFIED
+}
+
+type Inventory_SoftwarePackage_LanguagePackage struct {
+	LanguagePackage *Inventory_LanguagePackage
+}
+
+func (*Inventory_SoftwarePackage_LanguagePackage) isInventory_SoftwarePackage_Details() {}
+
+type ReportInventoryRequest struct {
+	Inventory           *Inventory
+	ReportFullInventory bool
+}
+
+// Inventory_PackageDelta is the added/changed/removed diff for one package
+// list (installed or available) since the last successful report.
+type Inventory_PackageDelta struct {
+	AddedPackages      []*Inventory_SoftwarePackage
+	ChangedPackages    []*Inventory_SoftwarePackage
+	RemovedPackageKeys []string
+}
+
+// ReportInventoryDeltaRequest carries only the packages that changed since
+// the last successful report, instead of the full inventory. Installed and
+// available packages are diffed separately since a package update can appear
+// or disappear from either list independent of the other.
+type ReportInventoryDeltaRequest struct {
+	OsInfo            *Inventory_OsInfo
+	InstalledPackages *Inventory_PackageDelta
+	AvailablePackages *Inventory_PackageDelta
+}
+
+type ReportInventoryResponse struct {
+	ReportFullInventory bool
+}
+
+func (m *ReportInventoryResponse) GetReportFullInventory() bool {
+	if m != nil {
+		return m.ReportFullInventory
+	}
+	return false
+}
+
+// AgentEndpointServiceClient is the subset of the generated service client
+// that the inventory reporting path calls.
+type AgentEndpointServiceClient interface {
+	ReportInventory(ctx context.Context, in *ReportInventoryRequest, opts ...grpc.CallOption) (*ReportInventoryResponse, error)
+	ReportInventoryDelta(ctx context.Context, in *ReportInventoryDeltaRequest, opts ...grpc.CallOption) (*ReportInventoryResponse, error)
+}
@@ -0,0 +1,52 @@
+package packages
+
+import (
+	"bytes"
+	"context"
+	"os/exec"
+	"regexp"
+	"strings"
+)
+
+var apkInfoArgs = []string{"info", "-v"}
+
+// apkReleaseSuffix matches the "-r<release>" suffix apk normally appends to a
+// package version, so it can be stripped off before splitting the remaining
+// "<name>-<version>" on its own last hyphen.
+var apkReleaseSuffix = regexp.MustCompile(`-r\d+$`)
+
+// InstalledApkPackages queries for all installed apk packages on Alpine-family hosts.
+func InstalledApkPackages(ctx context.Context) ([]PkgInfo, error) {
+	out, err := run(exec.CommandContext(ctx, "apk", apkInfoArgs...))
+	if err != nil {
+		return nil, err
+	}
+	return parseApkInfo(out), nil
+}
+
+// parseApkInfo parses the output of `apk info -v`, which lists one
+// "<name>-<version>-r<release>" token per installed package per line. A
+// package without a "-r<release>" suffix still has its name and version
+// recorded, split on the last remaining hyphen.
+func parseApkInfo(out []byte) []PkgInfo {
+	lines := bytes.Split(bytes.TrimSpace(out), []byte("\n"))
+	var pkgs []PkgInfo
+	for _, ln := range lines {
+		line := strings.TrimSpace(string(ln))
+		if line == "" {
+			continue
+		}
+
+		nameVersion := line
+		if loc := apkReleaseSuffix.FindStringIndex(line); loc != nil {
+			nameVersion = line[:loc[0]]
+		}
+
+		idx := strings.LastIndex(nameVersion, "-")
+		if idx < 0 {
+			continue
+		}
+		pkgs = append(pkgs, PkgInfo{Name: nameVersion[:idx], Arch: "all", Version: line[idx+1:]})
+	}
+	return pkgs
+}
@@ -0,0 +1,49 @@
+package packages
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+type cargoCrates2 struct {
+	Installs map[string]json.RawMessage `json:"installs"`
+}
+
+// InstalledCargoPackages reads ~/.cargo/.crates2.json to enumerate
+// cargo-installed binaries. It reads the inventory agent's own home
+// directory (typically root's), so per-user cargo installs under other
+// accounts' home directories are not seen.
+func InstalledCargoPackages(ctx context.Context) ([]PkgInfo, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(filepath.Join(home, ".cargo", ".crates2.json"))
+	if err != nil {
+		return nil, err
+	}
+	return parseCargoCrates2(data)
+}
+
+// parseCargoCrates2 parses .crates2.json, whose top-level "installs" keys look
+// like "<name> <version> (registry+https://...)".
+func parseCargoCrates2(data []byte) ([]PkgInfo, error) {
+	var crates cargoCrates2
+	if err := json.Unmarshal(data, &crates); err != nil {
+		return nil, err
+	}
+
+	var pkgs []PkgInfo
+	for key := range crates.Installs {
+		fields := strings.Fields(key)
+		if len(fields) < 2 {
+			continue
+		}
+		pkgs = append(pkgs, PkgInfo{Name: fields[0], Version: fields[1]})
+	}
+	return pkgs, nil
+}
@@ -0,0 +1,39 @@
+package packages
+
+import (
+	"bytes"
+	"context"
+	"os/exec"
+	"strings"
+)
+
+var gemListArgs = []string{"list", "--local"}
+
+// InstalledGemPackages queries for all locally installed gems.
+func InstalledGemPackages(ctx context.Context) ([]PkgInfo, error) {
+	out, err := run(exec.CommandContext(ctx, "gem", gemListArgs...))
+	if err != nil {
+		return nil, err
+	}
+	return parseGemList(out), nil
+}
+
+// parseGemList parses `gem list --local` output, which lists one
+// "<name> (<version>[, <version>...])" entry per line, newest version first.
+func parseGemList(out []byte) []PkgInfo {
+	lines := bytes.Split(bytes.TrimSpace(out), []byte("\n"))
+	var pkgs []PkgInfo
+	for _, ln := range lines {
+		line := strings.TrimSpace(string(ln))
+		if line == "" {
+			continue
+		}
+		name, rest, ok := strings.Cut(line, " (")
+		if !ok {
+			continue
+		}
+		version, _, _ := strings.Cut(strings.TrimSuffix(rest, ")"), ", ")
+		pkgs = append(pkgs, PkgInfo{Name: name, Version: version})
+	}
+	return pkgs
+}
@@ -0,0 +1,52 @@
+package packages
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+)
+
+var npmListArgs = []string{"ls", "-g", "--json", "--depth=0"}
+
+type npmListOutput struct {
+	Dependencies map[string]struct {
+		Version string `json:"version"`
+	} `json:"dependencies"`
+}
+
+// InstalledNpmPackages queries for all globally installed npm packages.
+func InstalledNpmPackages(ctx context.Context) ([]PkgInfo, error) {
+	// npm ls routinely exits non-zero (e.g. missing or extraneous peer
+	// dependencies) while still writing a valid package list to stdout, so
+	// unlike run()'s other callers a non-nil runErr isn't grounds to give up
+	// here — only bail if stdout doesn't parse either.
+	cmd := exec.CommandContext(ctx, "npm", npmListArgs...)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	runErr := cmd.Run()
+
+	pkgs, parseErr := parseNpmList(stdout.Bytes())
+	if parseErr != nil {
+		if runErr != nil {
+			return nil, fmt.Errorf("npm ls: %w: %s", runErr, stderr.String())
+		}
+		return nil, parseErr
+	}
+	return pkgs, nil
+}
+
+func parseNpmList(out []byte) ([]PkgInfo, error) {
+	var result npmListOutput
+	if err := json.Unmarshal(out, &result); err != nil {
+		return nil, err
+	}
+
+	var pkgs []PkgInfo
+	for name, dep := range result.Dependencies {
+		pkgs = append(pkgs, PkgInfo{Name: name, Version: dep.Version})
+	}
+	return pkgs, nil
+}
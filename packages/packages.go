@@ -0,0 +1,106 @@
+package packages
+
+import (
+	"bytes"
+	"context"
+	"os/exec"
+	"runtime"
+	"time"
+
+	"github.com/GoogleCloudPlatform/guest-logging-go/logger"
+)
+
+// PkgInfo describes a single installed or available package.
+type PkgInfo struct {
+	Name, Arch, Version string
+}
+
+// ZypperPatch describes a single zypper patch.
+type ZypperPatch struct {
+	Name, Category, Severity, Summary string
+}
+
+// WUAPackage describes a single Windows Update.
+type WUAPackage struct {
+	Title                    string
+	Description              string
+	Categories               []string
+	CategoryIDs              []string
+	KBArticleIDs             []string
+	MoreInfoURLs             []string
+	UpdateID                 string
+	RevisionNumber           int32
+	LastDeploymentChangeTime time.Time
+}
+
+// QFEPackage describes a single Windows QuickFixEngineering package.
+type QFEPackage struct {
+	Caption     string
+	Description string
+	HotFixID    string
+	InstalledOn string
+}
+
+// Packages holds the inventory collected from every package manager present
+// on the host. Only the fields populated by collectors available on the
+// current OS are non-nil.
+type Packages struct {
+	Apt           []PkgInfo
+	Deb           []PkgInfo
+	Yum           []PkgInfo
+	Rpm           []PkgInfo
+	Zypper        []PkgInfo
+	ZypperPatches []ZypperPatch
+	GooGet        []PkgInfo
+	WUA           []WUAPackage
+	QFE           []QFEPackage
+	Apk           []PkgInfo
+	Pacman        []PkgInfo
+	Pip           []PkgInfo
+	Gem           []PkgInfo
+	Npm           []PkgInfo
+	Cargo         []PkgInfo
+}
+
+// run executes cmd and returns its stdout, logging stderr on failure.
+func run(cmd *exec.Cmd) ([]byte, error) {
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, err
+	}
+	return stdout.Bytes(), nil
+}
+
+// Get collects installed package inventory from the apk and pacman (not on
+// Windows), npm, pip, gem and cargo package managers found on the host. The
+// other fields of Packages are not populated by Get.
+func Get(ctx context.Context) (Packages, error) {
+	var pkgs Packages
+
+	if runtime.GOOS != "windows" {
+		gatherIfPresent(ctx, "apk", &pkgs.Apk, InstalledApkPackages)
+		gatherIfPresent(ctx, "pacman", &pkgs.Pacman, InstalledPacmanPackages)
+	}
+	gatherIfPresent(ctx, "npm", &pkgs.Npm, InstalledNpmPackages)
+	gatherIfPresent(ctx, "pip", &pkgs.Pip, InstalledPipPackages)
+	gatherIfPresent(ctx, "gem", &pkgs.Gem, InstalledGemPackages)
+	gatherIfPresent(ctx, "cargo", &pkgs.Cargo, InstalledCargoPackages)
+
+	return pkgs, nil
+}
+
+// gatherIfPresent runs collect only when binary is on PATH, logging (not
+// failing) when the manager isn't installed or the collection errors.
+func gatherIfPresent(ctx context.Context, binary string, dst *[]PkgInfo, collect func(context.Context) ([]PkgInfo, error)) {
+	if _, err := exec.LookPath(binary); err != nil {
+		return
+	}
+	pkgs, err := collect(ctx)
+	if err != nil {
+		logger.Errorf("%s package collection failed: %v", binary, err)
+		return
+	}
+	*dst = pkgs
+}
@@ -0,0 +1,33 @@
+package packages
+
+import (
+	"bytes"
+	"context"
+	"os/exec"
+	"strings"
+)
+
+var pacmanQueryArgs = []string{"-Q"}
+
+// InstalledPacmanPackages queries for all installed pacman packages on Arch-family hosts.
+func InstalledPacmanPackages(ctx context.Context) ([]PkgInfo, error) {
+	out, err := run(exec.CommandContext(ctx, "pacman", pacmanQueryArgs...))
+	if err != nil {
+		return nil, err
+	}
+	return parsePacmanQuery(out), nil
+}
+
+// parsePacmanQuery parses the output of `pacman -Q`, one "<name> <version>" pair per line.
+func parsePacmanQuery(out []byte) []PkgInfo {
+	lines := bytes.Split(bytes.TrimSpace(out), []byte("\n"))
+	var pkgs []PkgInfo
+	for _, ln := range lines {
+		fields := strings.Fields(string(ln))
+		if len(fields) != 2 {
+			continue
+		}
+		pkgs = append(pkgs, PkgInfo{Name: fields[0], Arch: "all", Version: fields[1]})
+	}
+	return pkgs
+}
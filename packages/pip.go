@@ -0,0 +1,36 @@
+package packages
+
+import (
+	"context"
+	"encoding/json"
+	"os/exec"
+)
+
+var pipListArgs = []string{"list", "--format=json"}
+
+type pipPackage struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+// InstalledPipPackages queries for all packages installed in the default pip environment.
+func InstalledPipPackages(ctx context.Context) ([]PkgInfo, error) {
+	out, err := run(exec.CommandContext(ctx, "pip", pipListArgs...))
+	if err != nil {
+		return nil, err
+	}
+	return parsePipList(out)
+}
+
+func parsePipList(out []byte) ([]PkgInfo, error) {
+	var result []pipPackage
+	if err := json.Unmarshal(out, &result); err != nil {
+		return nil, err
+	}
+
+	pkgs := make([]PkgInfo, len(result))
+	for i, p := range result {
+		pkgs[i] = PkgInfo{Name: p.Name, Version: p.Version}
+	}
+	return pkgs, nil
+}